@@ -0,0 +1,59 @@
+// Copyright 2015 Daniel Theophanes.
+// Use of this source code is governed by a zlib-style
+// license that can be found in the LICENSE file.
+
+package service
+
+import "sync"
+
+// System represents an init system / service manager capable of detecting
+// whether it is the one controlling the current host and of constructing a
+// Service that drives it.
+type System interface {
+	// String returns a short identifier for the system, e.g.
+	// "linux-systemd", "windows-service", "darwin-launchd".
+	String() string
+
+	// Detect reports whether this System is the one managing the
+	// current host.
+	Detect() bool
+
+	// Interactive reports whether the program is running interactively,
+	// rather than having been started by this System.
+	Interactive() bool
+
+	// New constructs a Service for this System.
+	New(i Interface, c *Config) (Service, error)
+}
+
+var (
+	systemRegistry []System
+	system         System
+	systemOnce     sync.Once
+)
+
+// chooseSystem registers candidate init systems for the current platform,
+// in priority order. Each platform-specific file calls this from its own
+// init(), so only the backends built for the target GOOS ever register.
+func chooseSystem(a ...System) {
+	systemRegistry = append(systemRegistry, a...)
+}
+
+// resolveSystem picks the first registered candidate whose Detect reports
+// true, falling back to the last registered candidate so callers still get
+// a usable System rather than nil on a platform that only ships a single
+// backend.
+func resolveSystem() System {
+	systemOnce.Do(func() {
+		for _, choice := range systemRegistry {
+			if choice.Detect() {
+				system = choice
+				return
+			}
+		}
+		if len(systemRegistry) > 0 {
+			system = systemRegistry[len(systemRegistry)-1]
+		}
+	})
+	return system
+}
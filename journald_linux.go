@@ -0,0 +1,127 @@
+// Copyright 2015 Daniel Theophanes.
+// Use of this source code is governed by a zlib-style
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// journaldSocketPath is the native journald socket every systemd host
+// exposes; see systemd.journal-fields(7) and sd_journal_sendv(3).
+const journaldSocketPath = "/run/systemd/journal/socket"
+
+func init() {
+	RegisterLoggerBackend("linux-systemd", newJournaldLogger)
+}
+
+// journald syslog-compatible priority levels, per <sys/syslog.h>.
+const (
+	journaldPriorityErr     = 3
+	journaldPriorityWarning = 4
+	journaldPriorityInfo    = 6
+)
+
+type journaldLogger struct {
+	name string
+	conn *net.UnixConn
+	errs chan<- error
+}
+
+// newJournaldLogger dials the journald native socket over AF_UNIX
+// SOCK_DGRAM. It is registered as the logger backend for "linux-systemd"
+// and is selected automatically ahead of the syslog fallback on those
+// hosts.
+func newJournaldLogger(name string, errs chan<- error) (Logger, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: journaldSocketPath, Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	return &journaldLogger{name: name, conn: conn, errs: errs}, nil
+}
+
+func (l *journaldLogger) send(err error) error {
+	if err != nil && l.errs != nil {
+		l.errs <- err
+	}
+	return err
+}
+
+// write serializes fields as the journald native protocol: one
+// "FIELD=value\n" line per simple value, or "FIELD\n" followed by an
+// 8-byte little-endian length, the raw value, and a trailing newline for
+// any value containing a newline of its own.
+func (l *journaldLogger) write(priority int, msg string, fields map[string]interface{}) error {
+	var buf bytes.Buffer
+	writeJournaldField(&buf, "PRIORITY", strconv.Itoa(priority))
+	writeJournaldField(&buf, "SYSLOG_IDENTIFIER", l.name)
+	writeJournaldField(&buf, "MESSAGE", msg)
+	for k, v := range fields {
+		writeJournaldField(&buf, strings.ToUpper(k), fmt.Sprint(v))
+	}
+	_, err := l.conn.Write(buf.Bytes())
+	return l.send(err)
+}
+
+func writeJournaldField(buf *bytes.Buffer, key, value string) {
+	if !strings.Contains(value, "\n") {
+		buf.WriteString(key)
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+		return
+	}
+
+	buf.WriteString(key)
+	buf.WriteByte('\n')
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(value)))
+	buf.Write(size[:])
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+func (l *journaldLogger) Error(v ...interface{}) error {
+	return l.write(journaldPriorityErr, fmt.Sprint(v...), nil)
+}
+func (l *journaldLogger) Warning(v ...interface{}) error {
+	return l.write(journaldPriorityWarning, fmt.Sprint(v...), nil)
+}
+func (l *journaldLogger) Info(v ...interface{}) error {
+	return l.write(journaldPriorityInfo, fmt.Sprint(v...), nil)
+}
+func (l *journaldLogger) Errorf(format string, a ...interface{}) error {
+	return l.write(journaldPriorityErr, fmt.Sprintf(format, a...), nil)
+}
+func (l *journaldLogger) Warningf(format string, a ...interface{}) error {
+	return l.write(journaldPriorityWarning, fmt.Sprintf(format, a...), nil)
+}
+func (l *journaldLogger) Infof(format string, a ...interface{}) error {
+	return l.write(journaldPriorityInfo, fmt.Sprintf(format, a...), nil)
+}
+
+// structured satisfies the unexported interface AsStructuredLogger checks
+// for, exposing this logger's fields support.
+func (l *journaldLogger) structured() StructuredLogger {
+	return journaldStructuredLogger{l}
+}
+
+type journaldStructuredLogger struct {
+	*journaldLogger
+}
+
+func (l journaldStructuredLogger) Error(msg string, fields map[string]interface{}) error {
+	return l.write(journaldPriorityErr, msg, fields)
+}
+func (l journaldStructuredLogger) Warning(msg string, fields map[string]interface{}) error {
+	return l.write(journaldPriorityWarning, msg, fields)
+}
+func (l journaldStructuredLogger) Info(msg string, fields map[string]interface{}) error {
+	return l.write(journaldPriorityInfo, msg, fields)
+}
@@ -0,0 +1,42 @@
+// Copyright 2015 Daniel Theophanes.
+// Use of this source code is governed by a zlib-style
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestWriteJournaldField(t *testing.T) {
+	var buf bytes.Buffer
+	writeJournaldField(&buf, "MESSAGE", "hello")
+	if got, want := buf.String(), "MESSAGE=hello\n"; got != want {
+		t.Errorf("simple value: got %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	writeJournaldField(&buf, "MESSAGE", "line one\nline two")
+	out := buf.Bytes()
+	if !bytes.HasPrefix(out, []byte("MESSAGE\n")) {
+		t.Fatalf("multiline value should start with %q, got %q", "MESSAGE\n", out)
+	}
+	out = out[len("MESSAGE\n"):]
+	if len(out) < 8 {
+		t.Fatalf("multiline value missing 8-byte length prefix: %q", out)
+	}
+	size := binary.LittleEndian.Uint64(out[:8])
+	value := out[8:]
+	if !bytes.HasSuffix(value, []byte("\n")) {
+		t.Fatalf("multiline value should end with a trailing newline, got %q", value)
+	}
+	value = value[:len(value)-1]
+	if size != uint64(len(value)) {
+		t.Errorf("length prefix %d does not match value length %d", size, len(value))
+	}
+	if string(value) != "line one\nline two" {
+		t.Errorf("value = %q, want %q", value, "line one\nline two")
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2015 Daniel Theophanes.
+// Use of this source code is governed by a zlib-style
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"fmt"
+	"os"
+)
+
+// Logger writes to the system's log facility when running as a service, or
+// to the console when running interactively. Obtain one via Service.Logger
+// or Service.SystemLogger.
+type Logger interface {
+	Error(v ...interface{}) error
+	Warning(v ...interface{}) error
+	Info(v ...interface{}) error
+
+	Errorf(format string, a ...interface{}) error
+	Warningf(format string, a ...interface{}) error
+	Infof(format string, a ...interface{}) error
+}
+
+// StructuredLogger is implemented by Logger backends that can attach
+// key/value fields to a log entry rather than flattening everything to a
+// single message string. Obtain one from a Logger via AsStructuredLogger.
+type StructuredLogger interface {
+	Error(msg string, fields map[string]interface{}) error
+	Warning(msg string, fields map[string]interface{}) error
+	Info(msg string, fields map[string]interface{}) error
+}
+
+// AsStructuredLogger returns a StructuredLogger view of lg if its backend
+// supports structured fields, and false otherwise.
+func AsStructuredLogger(lg Logger) (StructuredLogger, bool) {
+	if s, ok := lg.(interface{ structured() StructuredLogger }); ok {
+		return s.structured(), true
+	}
+	return nil, false
+}
+
+// loggerBackend constructs a system Logger for the named service.
+type loggerBackend func(name string, errs chan<- error) (Logger, error)
+
+var loggerBackends = map[string]loggerBackend{}
+
+// RegisterLoggerBackend registers a Logger backend for the given System
+// identifier (as returned by System.String, e.g. "linux-systemd"). Backends
+// run under that system take priority over the default syslog/eventlog
+// logger; register before the service's SystemLogger is first called.
+func RegisterLoggerBackend(system string, factory loggerBackend) {
+	loggerBackends[system] = factory
+}
+
+// newSystemLogger dispatches to the Logger backend registered for the
+// detected System, falling back to fn when none is registered or the
+// registered backend fails to initialize.
+func newSystemLogger(name string, errs chan<- error, fn loggerBackend) (Logger, error) {
+	if factory, ok := loggerBackends[Platform()]; ok {
+		if lg, err := factory(name, errs); err == nil {
+			return lg, nil
+		}
+	}
+	return fn(name, errs)
+}
+
+type consoleLogger struct{}
+
+// ConsoleLogger logs to os.Stderr/os.Stdout. It is returned by
+// Service.Logger when the program is running interactively.
+var ConsoleLogger = consoleLogger{}
+
+func (consoleLogger) Error(v ...interface{}) error {
+	_, err := fmt.Fprintln(os.Stderr, v...)
+	return err
+}
+
+func (consoleLogger) Warning(v ...interface{}) error {
+	_, err := fmt.Fprintln(os.Stderr, v...)
+	return err
+}
+
+func (consoleLogger) Info(v ...interface{}) error {
+	_, err := fmt.Fprintln(os.Stdout, v...)
+	return err
+}
+
+func (consoleLogger) Errorf(format string, a ...interface{}) error {
+	_, err := fmt.Fprintf(os.Stderr, format+"\n", a...)
+	return err
+}
+
+func (consoleLogger) Warningf(format string, a ...interface{}) error {
+	_, err := fmt.Fprintf(os.Stderr, format+"\n", a...)
+	return err
+}
+
+func (consoleLogger) Infof(format string, a ...interface{}) error {
+	_, err := fmt.Fprintf(os.Stdout, format+"\n", a...)
+	return err
+}
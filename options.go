@@ -0,0 +1,127 @@
+// Copyright 2015 Daniel Theophanes.
+// Use of this source code is governed by a zlib-style
+// license that can be found in the LICENSE file.
+
+package service
+
+import "time"
+
+// KeyValue provides a map of platform-specific options. See the backend
+// that reads a given key for its effect and default. Most options are
+// opt-in: a key that is absent falls back to a documented default, so
+// existing callers that don't set it see no change in behavior.
+type KeyValue map[string]interface{}
+
+// bool returns the value of the given name, assuming the value is a bool.
+// If the key is not present or is not a bool, defaultValue is returned.
+func (kv KeyValue) bool(name string, defaultValue bool) bool {
+	if v, found := kv[name]; found {
+		if castValue, is := v.(bool); is {
+			return castValue
+		}
+	}
+	return defaultValue
+}
+
+// int returns the value of the given name, assuming the value is an int.
+// If the key is not present or is not an int, defaultValue is returned.
+func (kv KeyValue) int(name string, defaultValue int) int {
+	if v, found := kv[name]; found {
+		if castValue, is := v.(int); is {
+			return castValue
+		}
+	}
+	return defaultValue
+}
+
+// string returns the value of the given name, assuming the value is a
+// string. If the key is not present or is not a string, defaultValue is
+// returned.
+func (kv KeyValue) string(name string, defaultValue string) string {
+	if v, found := kv[name]; found {
+		if castValue, is := v.(string); is {
+			return castValue
+		}
+	}
+	return defaultValue
+}
+
+// stringArray returns the value of the given name, assuming the value is
+// a []string. If the key is not present or is not a []string, defaultValue
+// is returned.
+func (kv KeyValue) stringArray(name string, defaultValue []string) []string {
+	if v, found := kv[name]; found {
+		if castValue, is := v.([]string); is {
+			return castValue
+		}
+	}
+	return defaultValue
+}
+
+// calendarIntervals returns the value of the given name, assuming the
+// value is a []CalendarInterval. If the key is not present or is not a
+// []CalendarInterval, defaultValue is returned.
+func (kv KeyValue) calendarIntervals(name string, defaultValue []CalendarInterval) []CalendarInterval {
+	if v, found := kv[name]; found {
+		if castValue, is := v.([]CalendarInterval); is {
+			return castValue
+		}
+	}
+	return defaultValue
+}
+
+// duration returns the value of the given name, assuming the value is a
+// time.Duration. If the key is not present or is not a time.Duration,
+// defaultValue is returned.
+func (kv KeyValue) duration(name string, defaultValue time.Duration) time.Duration {
+	if v, found := kv[name]; found {
+		if castValue, is := v.(time.Duration); is {
+			return castValue
+		}
+	}
+	return defaultValue
+}
+
+// funcSingle returns the value of the given name, assuming the value is a
+// func(). If the key is not present or is not a func(), defaultValue is
+// returned.
+func (kv KeyValue) funcSingle(name string, defaultValue func()) func() {
+	if v, found := kv[name]; found {
+		if castValue, is := v.(func()); is {
+			return castValue
+		}
+	}
+	return defaultValue
+}
+
+// Options read by more than one backend. Platform- or backend-specific
+// options are declared alongside the file that reads them.
+const (
+	optionUserService        = "UserService"
+	optionUserServiceDefault = false
+
+	optionRunWait = "RunWait"
+
+	// Restart controls whether the init system should relaunch the
+	// service after it exits. Supported by upstart and systemd; true by
+	// default, matching the respawn/Restart=always behavior those
+	// backends have always rendered.
+	optionRestart        = "Restart"
+	optionRestartDefault = true
+
+	// KeepAlive, RunAtLoad, StartCalendarInterval, and StartInterval
+	// describe scheduling/keep-alive semantics. launchd renders them
+	// directly into the plist; systemd renders StartCalendarInterval
+	// and StartInterval into a companion <name>.timer unit, since
+	// systemd services themselves have no notion of a schedule.
+	optionKeepAlive        = "KeepAlive"
+	optionKeepAliveDefault = false
+
+	optionRunAtLoad        = "RunAtLoad"
+	optionRunAtLoadDefault = false
+
+	optionStartCalendarInterval = "StartCalendarInterval"
+
+	optionStartInterval        = "StartInterval"
+	optionStartIntervalDefault = 0
+)
@@ -0,0 +1,32 @@
+// Copyright 2015 Daniel Theophanes.
+// Use of this source code is governed by a zlib-style
+// license that can be found in the LICENSE file.
+
+package service
+
+// CalendarInterval describes a recurring point in time at which a service
+// should be started, using launchd's StartCalendarInterval / cron(5)
+// numbering. A field left at -1 matches every value, analogous to a "*" in
+// crontab. Pass a slice under the StartCalendarInterval option to schedule
+// more than one interval.
+type CalendarInterval struct {
+	Minute  int // 0-59, -1 for every minute
+	Hour    int // 0-23, -1 for every hour
+	Day     int // 1-31, -1 for every day
+	Weekday int // 0-7 (0 and 7 are both Sunday), -1 for every weekday
+	Month   int // 1-12, -1 for every month
+}
+
+// KeepAlive conditions a launchd job's automatic restart on runtime state
+// rather than keeping it alive unconditionally. Set the KeepAlive option to
+// a plain bool for the unconditional case, or to a KeepAlive value for
+// conditional semantics.
+type KeepAlive struct {
+	// SuccessfulExit selects which exits trigger a restart: true
+	// restarts only after a clean (status 0) exit, false only after a
+	// failing one.
+	SuccessfulExit bool
+	// NetworkState, when true, (re)starts the job whenever the host
+	// gains network connectivity.
+	NetworkState bool
+}
@@ -0,0 +1,147 @@
+// Copyright 2015 Daniel Theophanes.
+// Use of this source code is governed by a zlib-style
+// license that can be found in the LICENSE file.
+
+// Package service provides a simple way to install, control, and run a
+// program as a system service across the major platform init systems.
+package service
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// ErrNoServiceSystemDetected is returned by New when no supported init
+// system could be detected on the current host.
+var ErrNoServiceSystemDetected = errors.New("service: no service system detected")
+
+// Interface is implemented by the caller's program and is driven by the
+// Service once it is running under the chosen init system.
+type Interface interface {
+	// Start provides a place to initiate the service. Start should not
+	// block; use a goroutine to run anything that would.
+	Start(s Service) error
+
+	// Stop provides a place to clean up program execution before it is
+	// terminated. It should not take more than a few seconds to execute.
+	// Stop should not call os.Exit directly in the function.
+	Stop(s Service) error
+}
+
+// Service represents a service that can be run or controlled on the host
+// operating system. Use New to obtain one for the detected init system.
+type Service interface {
+	// Run should be called shortly after the program entry point.
+	// After Interface.Stop has finished running, Run will stop blocking.
+	// After Run stops blocking, the program must exit shortly after.
+	Run() error
+
+	// Start signals to the OS service manager the given service should
+	// start.
+	Start() error
+
+	// Stop signals to the OS service manager the given service should
+	// stop.
+	Stop() error
+
+	// Restart signals to the OS service manager the given service should
+	// restart.
+	Restart() error
+
+	// Install sets up a service. Depending on the system, this may
+	// require greater rights. Will return an error if it is already
+	// installed.
+	Install() error
+
+	// Uninstall removes a service. Will return an error if the service
+	// is not present.
+	Uninstall() error
+
+	// Status returns the current status of the service, e.g. whether it
+	// is running, stopped, or not installed at all. Returns
+	// StatusNotInstalled rather than an error when the service is simply
+	// absent.
+	Status() (Status, error)
+
+	// Logger opens and returns a system logger. If the program is
+	// running interactively rather than as a service, the returned
+	// logger writes to the console. If the program is running as a
+	// service, the returned logger writes to the system log.
+	Logger(errs chan<- error) (Logger, error)
+
+	// SystemLogger opens and returns a logger that always writes to the
+	// system log, passing any write errors to errs.
+	SystemLogger(errs chan<- error) (Logger, error)
+
+	// String displays the name of the service. The display name if
+	// present, otherwise the name.
+	String() string
+}
+
+// Config provides the setup for a Service. The Name field is required.
+type Config struct {
+	Name        string
+	DisplayName string
+	Description string
+
+	UserName         string
+	Arguments        []string
+	Executable       string // Path to the executable. Defaults to the running binary.
+	ChRoot           string
+	WorkingDirectory string
+	Dependencies     []string
+
+	// Option is a map of platform-specific settings. Keys are typically
+	// opt-in and documented alongside the backend that reads them; a key
+	// that is absent falls back to a documented default so existing
+	// callers see no change in behavior.
+	Option KeyValue
+}
+
+// execPath returns the absolute path to the executable that should be run,
+// defaulting to the currently running binary when Config.Executable is
+// unset.
+func (c *Config) execPath() (string, error) {
+	if len(c.Executable) != 0 {
+		return filepath.Abs(c.Executable)
+	}
+	return os.Executable()
+}
+
+// New creates a new service based on the detected init system for the
+// current host.
+func New(i Interface, c *Config) (Service, error) {
+	s := resolveSystem()
+	if s == nil {
+		return nil, ErrNoServiceSystemDetected
+	}
+	return s.New(i, c)
+}
+
+// Platform returns a short identifier for the detected init system, e.g.
+// "linux-systemd", "windows-service", "darwin-launchd". It returns "" if
+// no system could be detected.
+func Platform() string {
+	s := resolveSystem()
+	if s == nil {
+		return ""
+	}
+	return s.String()
+}
+
+// Interactive reports whether the program is running interactively rather
+// than having been started by the init system.
+func Interactive() bool {
+	s := resolveSystem()
+	if s == nil {
+		return true
+	}
+	return s.Interactive()
+}
+
+func run(command string, arguments ...string) error {
+	cmd := exec.Command(command, arguments...)
+	return cmd.Run()
+}
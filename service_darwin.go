@@ -0,0 +1,249 @@
+// Copyright 2015 Daniel Theophanes.
+// Use of this source code is governed by a zlib-style
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"text/template"
+	"time"
+)
+
+type darwinLaunchdSystem struct{}
+
+func (darwinLaunchdSystem) String() string    { return "darwin-launchd" }
+func (darwinLaunchdSystem) Detect() bool      { return true }
+func (darwinLaunchdSystem) Interactive() bool { return isInteractive() }
+func (darwinLaunchdSystem) New(i Interface, c *Config) (Service, error) {
+	return &darwinLaunchdService{i: i, Config: c}, nil
+}
+
+func init() {
+	chooseSystem(darwinLaunchdSystem{})
+}
+
+type darwinLaunchdService struct {
+	i Interface
+	*Config
+}
+
+func (s *darwinLaunchdService) String() string {
+	if len(s.DisplayName) > 0 {
+		return s.DisplayName
+	}
+	return s.Name
+}
+
+func (s *darwinLaunchdService) getHomeDir() (string, error) {
+	if s.Option.bool(optionUserService, optionUserServiceDefault) {
+		return os.UserHomeDir()
+	}
+	return "", nil
+}
+
+func (s *darwinLaunchdService) confPath() (string, error) {
+	if s.Option.bool(optionUserService, optionUserServiceDefault) {
+		homeDir, err := s.getHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return homeDir + "/Library/LaunchAgents/" + s.Name + ".plist", nil
+	}
+	return "/Library/LaunchDaemons/" + s.Name + ".plist", nil
+}
+
+func (s *darwinLaunchdService) template() *template.Template {
+	return template.Must(template.New("").Funcs(tf).Parse(launchdConfig))
+}
+
+func (s *darwinLaunchdService) Install() error {
+	confPath, err := s.confPath()
+	if err != nil {
+		return err
+	}
+	_, err = os.Stat(confPath)
+	if err == nil {
+		return fmt.Errorf("Init already exists: %s", confPath)
+	}
+
+	f, err := os.Create(confPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	path, err := s.execPath()
+	if err != nil {
+		return err
+	}
+
+	keepAliveAlways, keepAliveCond := s.keepAlive()
+
+	var to = &struct {
+		*Config
+		Path string
+
+		KeepAliveAlways bool
+		KeepAliveCond   *KeepAlive
+		RunAtLoad       bool
+		Calendars       []CalendarInterval
+		StartInterval   int
+	}{
+		s.Config,
+		path,
+
+		keepAliveAlways,
+		keepAliveCond,
+		s.Option.bool(optionRunAtLoad, optionRunAtLoadDefault),
+		s.Option.calendarIntervals(optionStartCalendarInterval, nil),
+		s.Option.int(optionStartInterval, optionStartIntervalDefault),
+	}
+
+	return s.template().Execute(f, to)
+}
+
+// keepAlive reads the KeepAlive option, which may be either a plain bool
+// (unconditional keep-alive) or a KeepAlive value (conditional keep-alive).
+// At most one return value is non-zero/non-nil; both are zero when the
+// option is false, absent, or of another type.
+func (s *darwinLaunchdService) keepAlive() (always bool, cond *KeepAlive) {
+	switch v := s.Option[optionKeepAlive].(type) {
+	case bool:
+		return v, nil
+	case KeepAlive:
+		return false, &v
+	}
+	return optionKeepAliveDefault, nil
+}
+
+func (s *darwinLaunchdService) Uninstall() error {
+	cp, err := s.confPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(cp); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *darwinLaunchdService) Logger(errs chan<- error) (Logger, error) {
+	if isInteractive() {
+		return ConsoleLogger, nil
+	}
+	return s.SystemLogger(errs)
+}
+
+func (s *darwinLaunchdService) SystemLogger(errs chan<- error) (Logger, error) {
+	return newSystemLogger(s.Name, errs, newSysLogger)
+}
+
+func (s *darwinLaunchdService) Run() (err error) {
+	err = s.i.Start(s)
+	if err != nil {
+		return err
+	}
+
+	s.Option.funcSingle(optionRunWait, func() {
+		var sigChan = make(chan os.Signal, 3)
+		signal.Notify(sigChan, os.Interrupt, os.Kill)
+		<-sigChan
+	})()
+
+	return s.i.Stop(s)
+}
+
+func (s *darwinLaunchdService) Start() error {
+	return run("launchctl", "load", mustConfPath(s))
+}
+
+func (s *darwinLaunchdService) Stop() error {
+	return run("launchctl", "unload", mustConfPath(s))
+}
+
+func (s *darwinLaunchdService) Restart() error {
+	err := s.Stop()
+	if err != nil {
+		return err
+	}
+	time.Sleep(50 * time.Millisecond)
+	return s.Start()
+}
+
+func mustConfPath(s *darwinLaunchdService) string {
+	cp, err := s.confPath()
+	if err != nil {
+		return ""
+	}
+	return cp
+}
+
+func (s *darwinLaunchdService) Status() (Status, error) {
+	cp, err := s.confPath()
+	if err != nil {
+		return StatusUnknown, err
+	}
+	if _, err := os.Stat(cp); err != nil {
+		return StatusNotInstalled, nil
+	}
+
+	out, err := exec.Command("launchctl", "list").Output()
+	if err != nil {
+		return StatusUnknown, err
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[2] != s.Name {
+			continue
+		}
+		if fields[0] == "-" {
+			return StatusStopped, nil
+		}
+		return StatusRunning, nil
+	}
+	return StatusStopped, nil
+}
+
+const launchdConfig = `<?xml version='1.0' encoding='UTF-8'?>
+<!DOCTYPE plist PUBLIC "-//Apple Computer//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd" >
+<plist version='1.0'>
+<dict>
+<key>Label</key><string>{{html .Name}}</string>
+<key>ProgramArguments</key>
+<array>
+        <string>{{html .Path}}</string>
+{{range .Arguments}}        <string>{{html .}}</string>
+{{end}}
+</array>
+{{if .UserName}}<key>UserName</key><string>{{html .UserName}}</string>{{end}}
+{{if .ChRoot}}<key>RootDirectory</key><string>{{html .ChRoot}}</string>{{end}}
+{{if .WorkingDirectory}}<key>WorkingDirectory</key><string>{{html .WorkingDirectory}}</string>{{end}}
+<key>SessionCreate</key><true/>
+{{if .KeepAliveAlways}}<key>KeepAlive</key><true/>
+{{else if .KeepAliveCond}}<key>KeepAlive</key>
+<dict>
+	<key>SuccessfulExit</key><{{if .KeepAliveCond.SuccessfulExit}}true{{else}}false{{end}}/>
+	{{if .KeepAliveCond.NetworkState}}<key>NetworkState</key><true/>
+	{{end}}</dict>
+{{else}}<key>KeepAlive</key><false/>
+{{end}}<key>RunAtLoad</key><{{if .RunAtLoad}}true{{else}}false{{end}}/>
+{{if .StartInterval}}<key>StartInterval</key><integer>{{.StartInterval}}</integer>
+{{end}}{{if .Calendars}}<key>StartCalendarInterval</key>
+<array>
+{{range .Calendars}}	<dict>
+{{if ge .Minute 0}}		<key>Minute</key><integer>{{.Minute}}</integer>
+{{end}}{{if ge .Hour 0}}		<key>Hour</key><integer>{{.Hour}}</integer>
+{{end}}{{if ge .Day 0}}		<key>Day</key><integer>{{.Day}}</integer>
+{{end}}{{if ge .Weekday 0}}		<key>Weekday</key><integer>{{.Weekday}}</integer>
+{{end}}{{if ge .Month 0}}		<key>Month</key><integer>{{.Month}}</integer>
+{{end}}	</dict>
+{{end}}</array>
+{{end}}<key>Disabled</key><false/>
+</dict>
+</plist>
+`
@@ -0,0 +1,183 @@
+// Copyright 2015 Daniel Theophanes.
+// Use of this source code is governed by a zlib-style
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"text/template"
+	"time"
+)
+
+type freebsdSystem struct{}
+
+func (freebsdSystem) String() string    { return "freebsd" }
+func (freebsdSystem) Detect() bool      { return true }
+func (freebsdSystem) Interactive() bool { return isInteractive() }
+func (freebsdSystem) New(i Interface, c *Config) (Service, error) {
+	return &freebsdService{i: i, Config: c}, nil
+}
+
+func init() {
+	chooseSystem(freebsdSystem{})
+}
+
+type freebsdService struct {
+	i Interface
+	*Config
+}
+
+func (s *freebsdService) String() string {
+	if len(s.DisplayName) > 0 {
+		return s.DisplayName
+	}
+	return s.Name
+}
+
+func (s *freebsdService) configPath() (cp string, err error) {
+	cp = "/usr/local/etc/rc.d/" + s.Config.Name
+	return
+}
+
+func (s *freebsdService) template() *template.Template {
+	return template.Must(template.New("").Funcs(tf).Parse(rcdScript))
+}
+
+func (s *freebsdService) Install() error {
+	confPath, err := s.configPath()
+	if err != nil {
+		return err
+	}
+	_, err = os.Stat(confPath)
+	if err == nil {
+		return fmt.Errorf("Init already exists: %s", confPath)
+	}
+
+	f, err := os.Create(confPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err = os.Chmod(confPath, 0755); err != nil {
+		return err
+	}
+
+	path, err := s.execPath()
+	if err != nil {
+		return err
+	}
+
+	var to = &struct {
+		*Config
+		Path string
+	}{
+		s.Config,
+		path,
+	}
+
+	return s.template().Execute(f, to)
+}
+
+func (s *freebsdService) Uninstall() error {
+	cp, err := s.configPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(cp); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *freebsdService) Logger(errs chan<- error) (Logger, error) {
+	if isInteractive() {
+		return ConsoleLogger, nil
+	}
+	return s.SystemLogger(errs)
+}
+
+func (s *freebsdService) SystemLogger(errs chan<- error) (Logger, error) {
+	return newSystemLogger(s.Name, errs, newSysLogger)
+}
+
+func (s *freebsdService) Run() (err error) {
+	err = s.i.Start(s)
+	if err != nil {
+		return err
+	}
+
+	s.Option.funcSingle(optionRunWait, func() {
+		var sigChan = make(chan os.Signal, 3)
+		signal.Notify(sigChan, os.Interrupt, os.Kill)
+		<-sigChan
+	})()
+
+	return s.i.Stop(s)
+}
+
+func (s *freebsdService) Start() error {
+	return run("service", s.Name, "start")
+}
+
+func (s *freebsdService) Stop() error {
+	return run("service", s.Name, "stop")
+}
+
+func (s *freebsdService) Restart() error {
+	err := s.Stop()
+	if err != nil {
+		return err
+	}
+	time.Sleep(50 * time.Millisecond)
+	return s.Start()
+}
+
+func (s *freebsdService) Status() (Status, error) {
+	cp, err := s.configPath()
+	if err != nil {
+		return StatusUnknown, err
+	}
+	if _, err := os.Stat(cp); err != nil {
+		return StatusNotInstalled, nil
+	}
+
+	out, err := exec.Command("service", s.Name, "onestatus").CombinedOutput()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return StatusStopped, nil
+		}
+		return StatusUnknown, err
+	}
+	if strings.Contains(string(out), "is running") {
+		return StatusRunning, nil
+	}
+	return StatusStopped, nil
+}
+
+const rcdScript = `#!/bin/sh
+#
+# PROVIDE: {{.Name}}
+# REQUIRE: LOGIN{{range .Dependencies}} {{.}}{{end}}
+# KEYWORD: shutdown
+
+. /etc/rc.subr
+
+name="{{.Name}}"
+{{if .DisplayName}}desc="{{.DisplayName}}"
+{{end}}rcvar="{{.Name}}_enable"
+
+load_rc_config "$name"
+: ${{"{"}}{{.Name}}_enable:="YES"}
+
+pidfile="/var/run/${name}.pid"
+command="/usr/sbin/daemon"
+command_args="-P ${pidfile} -r{{if .UserName}} -u {{.UserName}}{{end}} -- /bin/sh -c '{{if .ChRoot}}exec chroot {{.ChRoot}} {{end}}{{if .WorkingDirectory}}cd {{.WorkingDirectory}} && {{end}}exec {{.Path|cmdEscape}}{{range .Arguments}} {{.|cmd}}{{end}}'"
+
+run_rc_command "$1"
+`
@@ -0,0 +1,205 @@
+// Copyright 2015 Daniel Theophanes.
+// Use of this source code is governed by a zlib-style
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"text/template"
+	"time"
+)
+
+type openbsdSystem struct{}
+
+func (openbsdSystem) String() string    { return "openbsd" }
+func (openbsdSystem) Detect() bool      { return true }
+func (openbsdSystem) Interactive() bool { return isInteractive() }
+func (openbsdSystem) New(i Interface, c *Config) (Service, error) {
+	return &openbsdService{i: i, Config: c}, nil
+}
+
+func init() {
+	chooseSystem(openbsdSystem{})
+}
+
+// Option read from Config.Option: a space-separated list of services this
+// one should start after, rendered as rc.subr's rc_requires.
+const optionRcRequires = "rc_requires"
+
+type openbsdService struct {
+	i Interface
+	*Config
+}
+
+func (s *openbsdService) String() string {
+	if len(s.DisplayName) > 0 {
+		return s.DisplayName
+	}
+	return s.Name
+}
+
+func (s *openbsdService) configPath() (cp string, err error) {
+	cp = "/etc/rc.d/" + s.Config.Name
+	return
+}
+
+func (s *openbsdService) template() *template.Template {
+	return template.Must(template.New("").Funcs(tf).Parse(openbsdRcdScript))
+}
+
+func (s *openbsdService) hasRcctl() bool {
+	_, err := exec.LookPath("rcctl")
+	return err == nil
+}
+
+func (s *openbsdService) Install() error {
+	confPath, err := s.configPath()
+	if err != nil {
+		return err
+	}
+	_, err = os.Stat(confPath)
+	if err == nil {
+		return fmt.Errorf("Init already exists: %s", confPath)
+	}
+
+	f, err := os.Create(confPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err = os.Chmod(confPath, 0755); err != nil {
+		return err
+	}
+
+	path, err := s.execPath()
+	if err != nil {
+		return err
+	}
+
+	var to = &struct {
+		*Config
+		Path       string
+		RcRequires string
+	}{
+		s.Config,
+		path,
+		s.Option.string(optionRcRequires, ""),
+	}
+
+	return s.template().Execute(f, to)
+}
+
+func (s *openbsdService) Uninstall() error {
+	cp, err := s.configPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(cp); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *openbsdService) Logger(errs chan<- error) (Logger, error) {
+	if isInteractive() {
+		return ConsoleLogger, nil
+	}
+	return s.SystemLogger(errs)
+}
+
+func (s *openbsdService) SystemLogger(errs chan<- error) (Logger, error) {
+	return newSystemLogger(s.Name, errs, newSysLogger)
+}
+
+func (s *openbsdService) Run() (err error) {
+	err = s.i.Start(s)
+	if err != nil {
+		return err
+	}
+
+	s.Option.funcSingle(optionRunWait, func() {
+		var sigChan = make(chan os.Signal, 3)
+		signal.Notify(sigChan, os.Interrupt, os.Kill)
+		<-sigChan
+	})()
+
+	return s.i.Stop(s)
+}
+
+func (s *openbsdService) Start() error {
+	if s.hasRcctl() {
+		return run("rcctl", "start", s.Name)
+	}
+	return run("/etc/rc.d/"+s.Name, "start")
+}
+
+func (s *openbsdService) Stop() error {
+	if s.hasRcctl() {
+		return run("rcctl", "stop", s.Name)
+	}
+	return run("/etc/rc.d/"+s.Name, "stop")
+}
+
+func (s *openbsdService) Restart() error {
+	err := s.Stop()
+	if err != nil {
+		return err
+	}
+	time.Sleep(50 * time.Millisecond)
+	return s.Start()
+}
+
+func (s *openbsdService) Status() (Status, error) {
+	cp, err := s.configPath()
+	if err != nil {
+		return StatusUnknown, err
+	}
+	if _, err := os.Stat(cp); err != nil {
+		return StatusNotInstalled, nil
+	}
+
+	var out []byte
+	if s.hasRcctl() {
+		out, err = exec.Command("rcctl", "check", s.Name).CombinedOutput()
+	} else {
+		out, err = exec.Command("/etc/rc.d/"+s.Name, "check").CombinedOutput()
+	}
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return StatusStopped, nil
+		}
+		return StatusUnknown, err
+	}
+	if strings.Contains(string(out), "(ok)") || strings.Contains(string(out), "is running") {
+		return StatusRunning, nil
+	}
+	return StatusStopped, nil
+}
+
+const openbsdRcdScript = `#!/bin/ksh
+{{if .RcRequires}}
+rc_requires="{{.RcRequires}}"
+{{end}}
+daemon="{{.Path|cmdEscape}}"
+daemon_flags="{{range .Arguments}} {{.|cmd}}{{end}}"
+{{if .UserName}}daemon_user="{{.UserName}}"
+{{end}}
+rc_bg=YES
+rc_reload=NO
+
+. /etc/rc.d/rc.subr
+
+{{if .WorkingDirectory}}rc_start() {
+	cd {{.WorkingDirectory}}
+	rc_exec "${daemon} ${daemon_flags}"
+}
+{{end}}
+
+rc_cmd $1
+`
@@ -0,0 +1,364 @@
+// Copyright 2015 Daniel Theophanes.
+// Use of this source code is governed by a zlib-style
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"text/template"
+	"time"
+)
+
+func isSystemd() bool {
+	if _, err := os.Stat("/run/systemd/system"); err == nil {
+		return true
+	}
+	return false
+}
+
+type linuxSystemdSystem struct{}
+
+func (linuxSystemdSystem) String() string    { return "linux-systemd" }
+func (linuxSystemdSystem) Detect() bool      { return isSystemd() }
+func (linuxSystemdSystem) Interactive() bool { return isInteractive() }
+func (linuxSystemdSystem) New(i Interface, c *Config) (Service, error) {
+	return &linuxSystemdService{i: i, Config: c}, nil
+}
+
+func init() {
+	chooseSystem(linuxSystemdSystem{})
+}
+
+type linuxSystemdService struct {
+	i Interface
+	*Config
+}
+
+func (s *linuxSystemdService) String() string {
+	if len(s.DisplayName) > 0 {
+		return s.DisplayName
+	}
+	return s.Name
+}
+
+func (s *linuxSystemdService) configPath() (cp string, err error) {
+	if s.Option.bool(optionUserService, optionUserServiceDefault) {
+		err = fmt.Errorf("User services are not supported on systemd")
+		return
+	}
+	cp = "/etc/systemd/system/" + s.Config.Name + ".service"
+	return
+}
+
+func (s *linuxSystemdService) template() *template.Template {
+	return template.Must(template.New("").Funcs(tf).Parse(systemdScript))
+}
+
+// Resource-limit and hardening options read from Config.Option. Limits
+// default to -1, which omits the corresponding directive and leaves the
+// systemd-wide default untouched; hardening options default to off so
+// existing unit files render unchanged unless a caller opts in.
+const (
+	optionLimitNOFILE        = "LimitNOFILE"
+	optionLimitNOFILEDefault = -1
+
+	optionLimitNPROC        = "LimitNPROC"
+	optionLimitNPROCDefault = -1
+
+	optionLimitMEMLOCK        = "LimitMEMLOCK"
+	optionLimitMEMLOCKDefault = -1
+
+	optionLimitCORE        = "LimitCORE"
+	optionLimitCOREDefault = -1
+
+	optionNoNewPrivileges        = "NoNewPrivileges"
+	optionNoNewPrivilegesDefault = false
+
+	optionPrivateTmp        = "PrivateTmp"
+	optionPrivateTmpDefault = false
+
+	optionProtectSystem        = "ProtectSystem"
+	optionProtectSystemDefault = ""
+
+	optionProtectHome        = "ProtectHome"
+	optionProtectHomeDefault = false
+
+	optionCapabilityBoundingSet = "CapabilityBoundingSet"
+	optionAmbientCapabilities   = "AmbientCapabilities"
+	optionReadWritePaths        = "ReadWritePaths"
+)
+
+func (s *linuxSystemdService) Install() error {
+	confPath, err := s.configPath()
+	if err != nil {
+		return err
+	}
+	_, err = os.Stat(confPath)
+	if err == nil {
+		return fmt.Errorf("Init already exists: %s", confPath)
+	}
+
+	f, err := os.Create(confPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	path, err := s.execPath()
+	if err != nil {
+		return err
+	}
+
+	var to = &struct {
+		*Config
+		Path string
+
+		LimitNOFILE  int
+		LimitNPROC   int
+		LimitMEMLOCK int
+		LimitCORE    int
+
+		NoNewPrivileges       bool
+		PrivateTmp            bool
+		ProtectSystem         string
+		ProtectHome           bool
+		CapabilityBoundingSet []string
+		AmbientCapabilities   []string
+		ReadWritePaths        []string
+
+		Restart bool
+	}{
+		s.Config,
+		path,
+
+		s.Option.int(optionLimitNOFILE, optionLimitNOFILEDefault),
+		s.Option.int(optionLimitNPROC, optionLimitNPROCDefault),
+		s.Option.int(optionLimitMEMLOCK, optionLimitMEMLOCKDefault),
+		s.Option.int(optionLimitCORE, optionLimitCOREDefault),
+
+		s.Option.bool(optionNoNewPrivileges, optionNoNewPrivilegesDefault),
+		s.Option.bool(optionPrivateTmp, optionPrivateTmpDefault),
+		s.Option.string(optionProtectSystem, optionProtectSystemDefault),
+		s.Option.bool(optionProtectHome, optionProtectHomeDefault),
+		s.Option.stringArray(optionCapabilityBoundingSet, nil),
+		s.Option.stringArray(optionAmbientCapabilities, nil),
+		s.Option.stringArray(optionReadWritePaths, nil),
+
+		s.Option.bool(optionRestart, optionRestartDefault),
+	}
+
+	if err = s.template().Execute(f, to); err != nil {
+		return err
+	}
+
+	if err = s.installTimer(); err != nil {
+		return err
+	}
+
+	return run("systemctl", "daemon-reload")
+}
+
+func (s *linuxSystemdService) Uninstall() error {
+	cp, err := s.configPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(cp); err != nil {
+		return err
+	}
+	if tp, err := s.timerPath(); err == nil {
+		if _, err := os.Stat(tp); err == nil {
+			run("systemctl", "disable", s.Name+".timer")
+			os.Remove(tp)
+		}
+	}
+	return run("systemctl", "daemon-reload")
+}
+
+func (s *linuxSystemdService) timerPath() (string, error) {
+	if s.Option.bool(optionUserService, optionUserServiceDefault) {
+		return "", fmt.Errorf("User services are not supported on systemd")
+	}
+	return "/etc/systemd/system/" + s.Config.Name + ".timer", nil
+}
+
+// installTimer writes and enables a companion <name>.timer unit when the
+// service's Config.Option requests calendar or interval scheduling. It is
+// a no-op when neither StartCalendarInterval nor StartInterval is set,
+// leaving behavior unchanged for existing callers.
+func (s *linuxSystemdService) installTimer() error {
+	calendars := s.Option.calendarIntervals(optionStartCalendarInterval, nil)
+	interval := s.Option.int(optionStartInterval, optionStartIntervalDefault)
+	if len(calendars) == 0 && interval <= 0 {
+		return nil
+	}
+
+	tp, err := s.timerPath()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(tp)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	onCalendars := make([]string, len(calendars))
+	for i, c := range calendars {
+		onCalendars[i] = onCalendar(c)
+	}
+
+	var to = &struct {
+		*Config
+		OnCalendars     []string
+		OnUnitActiveSec int
+	}{
+		s.Config,
+		onCalendars,
+		interval,
+	}
+
+	if err = template.Must(template.New("").Parse(systemdTimerScript)).Execute(f, to); err != nil {
+		return err
+	}
+
+	return run("systemctl", "enable", s.Name+".timer")
+}
+
+var systemdWeekdays = [...]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+// onCalendar renders a CalendarInterval as a systemd OnCalendar= expression,
+// e.g. {Hour: 4, Minute: 0, Weekday: -1, Day: -1, Month: -1} becomes
+// "*-*-* 04:00:00". A field of -1 becomes "*", matching every value.
+func onCalendar(ci CalendarInterval) string {
+	field := func(v int) string {
+		if v < 0 {
+			return "*"
+		}
+		return fmt.Sprintf("%02d", v)
+	}
+	expr := fmt.Sprintf("*-%s-%s %s:%s:00", field(ci.Month), field(ci.Day), field(ci.Hour), field(ci.Minute))
+	if ci.Weekday >= 0 {
+		expr = systemdWeekdays[ci.Weekday%7] + " " + expr
+	}
+	return expr
+}
+
+const systemdTimerScript = `[Unit]
+Description={{.Description}}
+
+[Timer]
+{{range .OnCalendars}}OnCalendar={{.}}
+{{end}}{{if .OnUnitActiveSec}}OnUnitActiveSec={{.OnUnitActiveSec}}s
+{{end}}
+[Install]
+WantedBy=timers.target
+`
+
+func (s *linuxSystemdService) Logger(errs chan<- error) (Logger, error) {
+	if isInteractive() {
+		return ConsoleLogger, nil
+	}
+	return s.SystemLogger(errs)
+}
+
+func (s *linuxSystemdService) SystemLogger(errs chan<- error) (Logger, error) {
+	return newSystemLogger(s.Name, errs, newSysLogger)
+}
+
+func (s *linuxSystemdService) Run() (err error) {
+	err = s.i.Start(s)
+	if err != nil {
+		return err
+	}
+
+	s.Option.funcSingle(optionRunWait, func() {
+		var sigChan = make(chan os.Signal, 3)
+		signal.Notify(sigChan, os.Interrupt, os.Kill)
+		<-sigChan
+	})()
+
+	return s.i.Stop(s)
+}
+
+func (s *linuxSystemdService) Start() error {
+	return run("systemctl", "start", s.Name+".service")
+}
+
+func (s *linuxSystemdService) Stop() error {
+	return run("systemctl", "stop", s.Name+".service")
+}
+
+func (s *linuxSystemdService) Restart() error {
+	err := s.Stop()
+	if err != nil {
+		return err
+	}
+	time.Sleep(50 * time.Millisecond)
+	return s.Start()
+}
+
+func (s *linuxSystemdService) Status() (Status, error) {
+	cp, err := s.configPath()
+	if err != nil {
+		return StatusUnknown, err
+	}
+	if _, err := os.Stat(cp); err != nil {
+		return StatusNotInstalled, nil
+	}
+
+	out, err := exec.Command("systemctl", "is-active", s.Name+".service").Output()
+	state := strings.TrimSpace(string(out))
+	switch state {
+	case "active":
+		return StatusRunning, nil
+	case "inactive", "failed":
+		return StatusStopped, nil
+	default:
+		if err != nil {
+			return StatusUnknown, nil
+		}
+		return StatusStopped, nil
+	}
+}
+
+const systemdScript = `[Unit]
+Description={{.Description}}
+ConditionFileIsExecutable={{.Path|cmdEscape}}
+{{range $i, $dep := .Dependencies}}{{$dep}}
+{{end}}
+
+[Service]
+StartLimitInterval=5
+StartLimitBurst=10
+ExecStart={{.Path|cmdEscape}}{{range .Arguments}} {{.|cmd}}{{end}}
+{{if .ChRoot}}RootDirectory={{.ChRoot}}{{end}}
+{{if .WorkingDirectory}}WorkingDirectory={{.WorkingDirectory}}{{end}}
+{{if .UserName}}User={{.UserName}}{{end}}
+{{if .Restart}}Restart=always
+RestartSec=120
+{{end}}
+
+{{if ge .LimitNOFILE 0}}LimitNOFILE={{.LimitNOFILE}}
+{{end}}{{if ge .LimitNPROC 0}}LimitNPROC={{.LimitNPROC}}
+{{end}}{{if ge .LimitMEMLOCK 0}}LimitMEMLOCK={{.LimitMEMLOCK}}
+{{end}}{{if ge .LimitCORE 0}}LimitCORE={{.LimitCORE}}
+{{end}}
+{{if .NoNewPrivileges}}NoNewPrivileges=yes
+{{end}}{{if .PrivateTmp}}PrivateTmp=yes
+{{end}}{{if .ProtectSystem}}ProtectSystem={{.ProtectSystem}}
+{{end}}{{if .ProtectHome}}ProtectHome=yes
+{{end}}{{if .CapabilityBoundingSet}}CapabilityBoundingSet={{range .CapabilityBoundingSet}}{{.}} {{end}}
+{{end}}{{if .AmbientCapabilities}}AmbientCapabilities={{range .AmbientCapabilities}}{{.}} {{end}}
+{{end}}{{range .ReadWritePaths}}ReadWritePaths={{.}}
+{{end}}
+
+[Install]
+WantedBy=multi-user.target
+`
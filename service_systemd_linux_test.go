@@ -0,0 +1,25 @@
+// Copyright 2015 Daniel Theophanes.
+// Use of this source code is governed by a zlib-style
+// license that can be found in the LICENSE file.
+
+package service
+
+import "testing"
+
+func TestOnCalendar(t *testing.T) {
+	cases := []struct {
+		ci   CalendarInterval
+		want string
+	}{
+		{CalendarInterval{Minute: -1, Hour: -1, Day: -1, Weekday: -1, Month: -1}, "*-*-* *:*:00"},
+		{CalendarInterval{Minute: 0, Hour: 4, Day: -1, Weekday: -1, Month: -1}, "*-*-* 04:00:00"},
+		{CalendarInterval{Minute: 30, Hour: 2, Day: 1, Weekday: -1, Month: 1}, "*-01-01 02:30:00"},
+		{CalendarInterval{Minute: 0, Hour: 9, Day: -1, Weekday: 1, Month: -1}, "Mon *-*-* 09:00:00"},
+		{CalendarInterval{Minute: 0, Hour: 9, Day: -1, Weekday: 7, Month: -1}, "Sun *-*-* 09:00:00"},
+	}
+	for _, c := range cases {
+		if got := onCalendar(c.ci); got != c.want {
+			t.Errorf("onCalendar(%+v) = %q, want %q", c.ci, got, c.want)
+		}
+	}
+}
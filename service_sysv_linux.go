@@ -0,0 +1,278 @@
+// Copyright 2015 Daniel Theophanes.
+// Use of this source code is governed by a zlib-style
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"text/template"
+	"time"
+)
+
+type sysvSystem struct{}
+
+func (sysvSystem) String() string    { return "linux-sysv" }
+func (sysvSystem) Interactive() bool { return isInteractive() }
+func (sysvSystem) Detect() bool {
+	// SysV init is the fallback Linux backend: it applies whenever the
+	// host isn't running systemd or Upstart.
+	return !isSystemd() && !isUpstart()
+}
+func (sysvSystem) New(i Interface, c *Config) (Service, error) {
+	return &sysvService{i: i, Config: c}, nil
+}
+
+func init() {
+	chooseSystem(sysvSystem{})
+}
+
+type sysvService struct {
+	i Interface
+	*Config
+}
+
+func (s *sysvService) String() string {
+	if len(s.DisplayName) > 0 {
+		return s.DisplayName
+	}
+	return s.Name
+}
+
+func (s *sysvService) configPath() (cp string, err error) {
+	cp = "/etc/init.d/" + s.Config.Name
+	return
+}
+
+func (s *sysvService) template() *template.Template {
+	return template.Must(template.New("").Funcs(tf).Parse(sysvScript))
+}
+
+func (s *sysvService) Install() error {
+	confPath, err := s.configPath()
+	if err != nil {
+		return err
+	}
+	_, err = os.Stat(confPath)
+	if err == nil {
+		return fmt.Errorf("Init already exists: %s", confPath)
+	}
+
+	f, err := os.Create(confPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err = os.Chmod(confPath, 0755); err != nil {
+		return err
+	}
+
+	path, err := s.execPath()
+	if err != nil {
+		return err
+	}
+
+	var to = &struct {
+		*Config
+		Path string
+	}{
+		s.Config,
+		path,
+	}
+
+	if err = s.template().Execute(f, to); err != nil {
+		return err
+	}
+
+	for _, i := range [...]string{"2", "3", "4", "5"} {
+		if err := os.Symlink(confPath, "/etc/rc"+i+".d/S50"+s.Name); err != nil && !os.IsExist(err) {
+			return err
+		}
+	}
+	for _, i := range [...]string{"0", "1", "6"} {
+		if err := os.Symlink(confPath, "/etc/rc"+i+".d/K02"+s.Name); err != nil && !os.IsExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *sysvService) Uninstall() error {
+	cp, err := s.configPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(cp); err != nil {
+		return err
+	}
+	for _, i := range [...]string{"2", "3", "4", "5"} {
+		os.Remove("/etc/rc" + i + ".d/S50" + s.Name)
+	}
+	for _, i := range [...]string{"0", "1", "6"} {
+		os.Remove("/etc/rc" + i + ".d/K02" + s.Name)
+	}
+	return nil
+}
+
+func (s *sysvService) Logger(errs chan<- error) (Logger, error) {
+	if isInteractive() {
+		return ConsoleLogger, nil
+	}
+	return s.SystemLogger(errs)
+}
+
+func (s *sysvService) SystemLogger(errs chan<- error) (Logger, error) {
+	return newSystemLogger(s.Name, errs, newSysLogger)
+}
+
+func (s *sysvService) Run() (err error) {
+	err = s.i.Start(s)
+	if err != nil {
+		return err
+	}
+
+	s.Option.funcSingle(optionRunWait, func() {
+		var sigChan = make(chan os.Signal, 3)
+		signal.Notify(sigChan, os.Interrupt, os.Kill)
+		<-sigChan
+	})()
+
+	return s.i.Stop(s)
+}
+
+func (s *sysvService) Start() error {
+	return run("service", s.Name, "start")
+}
+
+func (s *sysvService) Stop() error {
+	return run("service", s.Name, "stop")
+}
+
+func (s *sysvService) Restart() error {
+	err := s.Stop()
+	if err != nil {
+		return err
+	}
+	time.Sleep(50 * time.Millisecond)
+	return s.Start()
+}
+
+func (s *sysvService) Status() (Status, error) {
+	cp, err := s.configPath()
+	if err != nil {
+		return StatusUnknown, err
+	}
+	if _, err := os.Stat(cp); err != nil {
+		return StatusNotInstalled, nil
+	}
+
+	out, err := exec.Command("service", s.Name, "status").CombinedOutput()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return StatusStopped, nil
+		}
+		return StatusUnknown, err
+	}
+	if strings.Contains(string(out), "Running") {
+		return StatusRunning, nil
+	}
+	return StatusStopped, nil
+}
+
+const sysvScript = `#!/bin/sh
+# For RedHat and cousins:
+# chkconfig: - 99 01
+# description: {{.Description}}
+# processname: {{.Path}}
+
+### BEGIN INIT INFO
+# Provides:          {{.Path}}
+# Required-Start:
+# Required-Stop:
+# Default-Start:     2 3 4 5
+# Default-Stop:      0 1 6
+# Short-Description: {{.DisplayName}}
+# Description:       {{.Description}}
+### END INIT INFO
+
+cmd="{{.Path|cmdEscape}}{{range .Arguments}} {{.|cmd}}{{end}}"
+
+name="{{.Name}}"
+pid_file="/var/run/$name.pid"
+stdout_log="/var/log/$name.log"
+stderr_log="/var/log/$name.err"
+
+[ -e /etc/sysconfig/$name ] && . /etc/sysconfig/$name
+
+get_pid() {
+	cat "$pid_file"
+}
+
+is_running() {
+	[ -f "$pid_file" ] && ps -p "$(get_pid)" > /dev/null 2>&1
+}
+
+case "$1" in
+	start)
+		if is_running; then
+			echo "Already started"
+		else
+			echo "Starting $name"
+			{{if .WorkingDirectory}}cd "{{.WorkingDirectory}}"
+			{{end}}{{if .ChRoot}}chroot "{{.ChRoot}}" {{end}}{{if .UserName}}sudo -u "{{.UserName}}" {{end}}$cmd >> "$stdout_log" 2>> "$stderr_log" &
+			echo $! > "$pid_file"
+			if ! is_running; then
+				echo "Unable to start, see $stdout_log and $stderr_log"
+				exit 1
+			fi
+		fi
+	;;
+	stop)
+		if is_running; then
+			echo -n "Stopping $name.."
+			kill $(get_pid)
+			for i in $(seq 1 10); do
+				if ! is_running; then
+					break
+				fi
+				echo -n "."
+				sleep 1
+			done
+			echo
+			if is_running; then
+				echo "Not stopped; may still be shutting down or shutdown may have failed"
+				exit 1
+			else
+				echo "Stopped"
+				rm -f "$pid_file"
+			fi
+		else
+			echo "Not running"
+		fi
+	;;
+	restart)
+		$0 stop
+		$0 start
+	;;
+	status)
+		if is_running; then
+			echo "Running as pid $(get_pid)"
+		else
+			echo "Stopped"
+			exit 1
+		fi
+	;;
+	*)
+		echo "Usage: $0 {start|stop|restart|status}"
+		exit 1
+	;;
+esac
+
+exit 0
+`
@@ -30,6 +30,19 @@ func isUpstart() bool {
 	return false
 }
 
+type upstartSystem struct{}
+
+func (upstartSystem) String() string    { return "linux-upstart" }
+func (upstartSystem) Detect() bool      { return isUpstart() && !isSystemd() }
+func (upstartSystem) Interactive() bool { return isInteractive() }
+func (upstartSystem) New(i Interface, c *Config) (Service, error) {
+	return newUpstartService(i, c)
+}
+
+func init() {
+	chooseSystem(upstartSystem{})
+}
+
 type upstart struct {
 	i Interface
 	*Config
@@ -144,12 +157,14 @@ func (s *upstart) Install() error {
 		HasKillStanza      bool
 		HasSetUid          bool
 		HasStartStopDaemon bool
+		Restart            bool
 	}{
 		s.Config,
 		path,
 		s.hasKillStanza(),
 		s.hasSetUid(),
 		s.hasStartStopDaemon(),
+		s.Option.bool(optionRestart, optionRestartDefault),
 	}
 
 	return s.template().Execute(f, to)
@@ -173,7 +188,7 @@ func (s *upstart) Logger(errs chan<- error) (Logger, error) {
 	return s.SystemLogger(errs)
 }
 func (s *upstart) SystemLogger(errs chan<- error) (Logger, error) {
-	return newSysLogger(s.Name, errs)
+	return newSystemLogger(s.Name, errs, newSysLogger)
 }
 
 func (s *upstart) Run() (err error) {
@@ -208,6 +223,30 @@ func (s *upstart) Restart() error {
 	return s.Start()
 }
 
+// upstartStatusRe matches the "start/running, process N" portion of
+// `initctl status <name>` output; a job that isn't running reports
+// "stop/waiting" instead.
+var upstartStatusRe = regexp.MustCompile(`start/running, process \d+`)
+
+func (s *upstart) Status() (Status, error) {
+	cp, err := s.configPath()
+	if err != nil {
+		return StatusUnknown, err
+	}
+	if _, err := os.Stat(cp); err != nil {
+		return StatusNotInstalled, nil
+	}
+
+	out, err := exec.Command("initctl", "status", s.Name).Output()
+	if err != nil {
+		return StatusUnknown, err
+	}
+	if upstartStatusRe.Match(out) {
+		return StatusRunning, nil
+	}
+	return StatusStopped, nil
+}
+
 // The upstart script should stop with an INT or the Go runtime will terminate
 // the program before the Stop handler can run.
 const upstartScript = `# {{.Description}}
@@ -222,9 +261,9 @@ stop on runlevel [!2345]
 
 {{if and .UserName .HasSetUid}}setuid {{.UserName}}{{end}}
 
-respawn
+{{if .Restart}}respawn
 respawn limit 10 5
-umask 022
+{{end}}umask 022
 
 console none
 
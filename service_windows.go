@@ -0,0 +1,295 @@
+// Copyright 2015 Daniel Theophanes.
+// Use of this source code is governed by a zlib-style
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// Recovery options read from Config.Option, translated into SCM failure
+// actions during Install. OnFailure defaults to "none" so existing unit
+// installs behave exactly as before unless a caller opts in.
+const (
+	optionOnFailure        = "OnFailure" // "restart" or "none"
+	optionOnFailureDefault = "none"
+
+	optionOnFailureDelayDuration        = "OnFailureDelayDuration"
+	optionOnFailureDelayDurationDefault = 5 * time.Second
+
+	optionOnFailureResetPeriod        = "OnFailureResetPeriod"
+	optionOnFailureResetPeriodDefault = 24 * time.Hour
+
+	optionOnFailureMaxRestarts        = "OnFailureMaxRestarts"
+	optionOnFailureMaxRestartsDefault = 3
+)
+
+type windowsSystem struct{}
+
+func (windowsSystem) String() string    { return "windows-service" }
+func (windowsSystem) Detect() bool      { return true }
+func (windowsSystem) Interactive() bool { return !isWindowsService() }
+func (windowsSystem) New(i Interface, c *Config) (Service, error) {
+	return &windowsService{i: i, Config: c}, nil
+}
+
+func init() {
+	chooseSystem(windowsSystem{})
+}
+
+func isWindowsService() bool {
+	is, err := svc.IsWindowsService()
+	if err != nil {
+		return false
+	}
+	return is
+}
+
+type windowsService struct {
+	i Interface
+	*Config
+}
+
+func (s *windowsService) String() string {
+	if len(s.DisplayName) > 0 {
+		return s.DisplayName
+	}
+	return s.Name
+}
+
+func (s *windowsService) Install() error {
+	exepath, err := s.execPath()
+	if err != nil {
+		return err
+	}
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	ms, err := m.OpenService(s.Name)
+	if err == nil {
+		ms.Close()
+		return fmt.Errorf("service %s already exists", s.Name)
+	}
+
+	ms, err = m.CreateService(s.Name, exepath, mgr.Config{
+		DisplayName: s.DisplayName,
+		Description: s.Description,
+		StartType:   mgr.StartAutomatic,
+	}, s.Arguments...)
+	if err != nil {
+		return err
+	}
+	defer ms.Close()
+
+	if err := s.setRecoveryActions(ms); err != nil {
+		return err
+	}
+
+	return eventlog.InstallAsEventCreate(s.Name, eventlog.Error|eventlog.Warning|eventlog.Info)
+}
+
+// setRecoveryActions translates the OnFailure* options into SCM failure
+// actions via ChangeServiceConfig2(SERVICE_CONFIG_FAILURE_ACTIONS). It is a
+// no-op, leaving the service with no configured recovery actions, unless
+// OnFailure is set to "restart".
+func (s *windowsService) setRecoveryActions(ms *mgr.Service) error {
+	if s.Option.string(optionOnFailure, optionOnFailureDefault) != "restart" {
+		return nil
+	}
+
+	delay := s.Option.duration(optionOnFailureDelayDuration, optionOnFailureDelayDurationDefault)
+	resetPeriod := s.Option.duration(optionOnFailureResetPeriod, optionOnFailureResetPeriodDefault)
+	maxRestarts := s.Option.int(optionOnFailureMaxRestarts, optionOnFailureMaxRestartsDefault)
+
+	// The SCM repeats the last action in the list forever once the
+	// failure count exceeds the list's length, so a terminal NoAction
+	// entry is required to actually cap restarts at maxRestarts.
+	actions := make([]mgr.RecoveryAction, maxRestarts+1)
+	for i := 0; i < maxRestarts; i++ {
+		actions[i] = mgr.RecoveryAction{Type: mgr.ServiceRestart, Delay: delay}
+	}
+	actions[maxRestarts] = mgr.RecoveryAction{Type: mgr.NoAction}
+
+	return ms.SetRecoveryActions(actions, uint32(resetPeriod.Seconds()))
+}
+
+func (s *windowsService) Uninstall() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	ms, err := m.OpenService(s.Name)
+	if err != nil {
+		return err
+	}
+	defer ms.Close()
+
+	if err := ms.Delete(); err != nil {
+		return err
+	}
+	return eventlog.Remove(s.Name)
+}
+
+func (s *windowsService) Logger(errs chan<- error) (Logger, error) {
+	if !isWindowsService() {
+		return ConsoleLogger, nil
+	}
+	return s.SystemLogger(errs)
+}
+
+func (s *windowsService) SystemLogger(errs chan<- error) (Logger, error) {
+	return newSystemLogger(s.Name, errs, newWindowsLogger)
+}
+
+func (s *windowsService) Run() error {
+	return svc.Run(s.Name, &windowsServiceHandler{i: s.i, s: s})
+}
+
+type windowsServiceHandler struct {
+	i Interface
+	s *windowsService
+}
+
+func (h *windowsServiceHandler) Execute(args []string, r <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	changes <- svc.Status{State: svc.StartPending}
+
+	if err := h.i.Start(h.s); err != nil {
+		return true, 1
+	}
+
+	changes <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+loop:
+	for c := range r {
+		switch c.Cmd {
+		case svc.Interrogate:
+			changes <- c.CurrentStatus
+		case svc.Stop, svc.Shutdown:
+			break loop
+		}
+	}
+
+	changes <- svc.Status{State: svc.StopPending}
+	if err := h.i.Stop(h.s); err != nil {
+		return true, 2
+	}
+	return false, 0
+}
+
+func (s *windowsService) Start() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	ms, err := m.OpenService(s.Name)
+	if err != nil {
+		return err
+	}
+	defer ms.Close()
+
+	return ms.Start()
+}
+
+func (s *windowsService) Stop() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	ms, err := m.OpenService(s.Name)
+	if err != nil {
+		return err
+	}
+	defer ms.Close()
+
+	_, err = ms.Control(svc.Stop)
+	return err
+}
+
+func (s *windowsService) Restart() error {
+	err := s.Stop()
+	if err != nil {
+		return err
+	}
+	time.Sleep(50 * time.Millisecond)
+	return s.Start()
+}
+
+func (s *windowsService) Status() (Status, error) {
+	m, err := mgr.Connect()
+	if err != nil {
+		return StatusUnknown, err
+	}
+	defer m.Disconnect()
+
+	ms, err := m.OpenService(s.Name)
+	if err != nil {
+		return StatusNotInstalled, nil
+	}
+	defer ms.Close()
+
+	st, err := ms.Query()
+	if err != nil {
+		return StatusUnknown, err
+	}
+	switch st.State {
+	case svc.Running, svc.StartPending:
+		return StatusRunning, nil
+	default:
+		return StatusStopped, nil
+	}
+}
+
+func newWindowsLogger(name string, errs chan<- error) (Logger, error) {
+	w, err := eventlog.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &windowsLogger{w, errs}, nil
+}
+
+type windowsLogger struct {
+	*eventlog.Log
+	errs chan<- error
+}
+
+func (l *windowsLogger) send(err error) error {
+	if err != nil && l.errs != nil {
+		l.errs <- err
+	}
+	return err
+}
+
+func (l *windowsLogger) Error(v ...interface{}) error {
+	return l.send(l.Log.Error(3, fmt.Sprint(v...)))
+}
+func (l *windowsLogger) Warning(v ...interface{}) error {
+	return l.send(l.Log.Warning(2, fmt.Sprint(v...)))
+}
+func (l *windowsLogger) Info(v ...interface{}) error {
+	return l.send(l.Log.Info(1, fmt.Sprint(v...)))
+}
+func (l *windowsLogger) Errorf(format string, a ...interface{}) error {
+	return l.send(l.Log.Error(3, fmt.Sprintf(format, a...)))
+}
+func (l *windowsLogger) Warningf(format string, a ...interface{}) error {
+	return l.send(l.Log.Warning(2, fmt.Sprintf(format, a...)))
+}
+func (l *windowsLogger) Infof(format string, a ...interface{}) error {
+	return l.send(l.Log.Info(1, fmt.Sprintf(format, a...)))
+}
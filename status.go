@@ -0,0 +1,16 @@
+// Copyright 2015 Daniel Theophanes.
+// Use of this source code is governed by a zlib-style
+// license that can be found in the LICENSE file.
+
+package service
+
+// Status represents the current state of an installed service, as reported
+// by the underlying init system.
+type Status byte
+
+const (
+	StatusUnknown Status = iota
+	StatusRunning
+	StatusStopped
+	StatusNotInstalled
+)